@@ -0,0 +1,38 @@
+// Package logrusadapter adapts a github.com/sirupsen/logrus.FieldLogger to
+// the misp.Logger interface
+package logrusadapter
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/0xrawsec/golang-misp/misp"
+)
+
+// Adapter : misp.Logger implementation backed by a logrus.FieldLogger
+type Adapter struct {
+	logger logrus.FieldLogger
+}
+
+// New : wrap logger so it can be passed as MispConOptions.Logger
+// return (misp.Logger)
+func New(logger logrus.FieldLogger) misp.Logger {
+	return Adapter{logger: logger}
+}
+
+func (a Adapter) Debugf(format string, args ...interface{}) { a.logger.Debugf(format, args...) }
+func (a Adapter) Infof(format string, args ...interface{})  { a.logger.Infof(format, args...) }
+func (a Adapter) Warnf(format string, args ...interface{})  { a.logger.Warnf(format, args...) }
+func (a Adapter) Errorf(format string, args ...interface{}) { a.logger.Errorf(format, args...) }
+
+// With : misp.Logger implementation
+func (a Adapter) With(keyvals ...interface{}) misp.Logger {
+	fields := make(logrus.Fields, len(keyvals)/2)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			continue
+		}
+		fields[key] = keyvals[i+1]
+	}
+	return Adapter{logger: a.logger.WithFields(fields)}
+}
@@ -0,0 +1,42 @@
+// Package slogadapter adapts a log/slog.Logger to the misp.Logger interface
+package slogadapter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/0xrawsec/golang-misp/misp"
+)
+
+// Adapter : misp.Logger implementation backed by a *slog.Logger
+type Adapter struct {
+	logger *slog.Logger
+}
+
+// New : wrap logger so it can be passed as MispConOptions.Logger
+// return (misp.Logger)
+func New(logger *slog.Logger) misp.Logger {
+	return Adapter{logger: logger}
+}
+
+func (a Adapter) Debugf(format string, args ...interface{}) {
+	a.logger.Log(context.Background(), slog.LevelDebug, fmt.Sprintf(format, args...))
+}
+
+func (a Adapter) Infof(format string, args ...interface{}) {
+	a.logger.Log(context.Background(), slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+func (a Adapter) Warnf(format string, args ...interface{}) {
+	a.logger.Log(context.Background(), slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+func (a Adapter) Errorf(format string, args ...interface{}) {
+	a.logger.Log(context.Background(), slog.LevelError, fmt.Sprintf(format, args...))
+}
+
+// With : misp.Logger implementation
+func (a Adapter) With(keyvals ...interface{}) misp.Logger {
+	return Adapter{logger: a.logger.With(keyvals...)}
+}
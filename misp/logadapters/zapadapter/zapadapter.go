@@ -0,0 +1,30 @@
+// Package zapadapter adapts a go.uber.org/zap.SugaredLogger to the
+// misp.Logger interface
+package zapadapter
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/0xrawsec/golang-misp/misp"
+)
+
+// Adapter : misp.Logger implementation backed by a *zap.SugaredLogger
+type Adapter struct {
+	logger *zap.SugaredLogger
+}
+
+// New : wrap logger so it can be passed as MispConOptions.Logger
+// return (misp.Logger)
+func New(logger *zap.SugaredLogger) misp.Logger {
+	return Adapter{logger: logger}
+}
+
+func (a Adapter) Debugf(format string, args ...interface{}) { a.logger.Debugf(format, args...) }
+func (a Adapter) Infof(format string, args ...interface{})  { a.logger.Infof(format, args...) }
+func (a Adapter) Warnf(format string, args ...interface{})  { a.logger.Warnf(format, args...) }
+func (a Adapter) Errorf(format string, args ...interface{}) { a.logger.Errorf(format, args...) }
+
+// With : misp.Logger implementation
+func (a Adapter) With(keyvals ...interface{}) misp.Logger {
+	return Adapter{logger: a.logger.With(keyvals...)}
+}
@@ -0,0 +1,86 @@
+package misp
+
+import (
+	"fmt"
+	stdlog "log"
+	"strings"
+)
+
+// Logger : logging sink used by MispCon, pluggable via MispConOptions.Logger
+// so callers can route library logs through whatever structured logger their
+// own application already uses. Every diagnostic a MispCon emits goes
+// through its own Logger (see the log method below) instead of a
+// package-global level; LoggingMiddleware is the exception and logs through
+// the Logger passed to it. Two MispCon instances in the same process can log
+// independently and neither panics nor calls os.Exit. This only covers this
+// library's own logging: github.com/0xrawsec/golang-utils/log is an external
+// dependency this repository doesn't vendor or control, and misp_test.go's
+// init() still configures it directly to drive the live-server integration
+// tests, which is orthogonal to MispCon's logging.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	// With returns a Logger that prefixes every subsequent message with the
+	// given key/value pairs, without mutating the receiver
+	With(keyvals ...interface{}) Logger
+}
+
+// log returns mc's configured Logger, falling back to the standard
+// library-backed default when none was set via MispConOptions
+func (mc MispCon) log() Logger {
+	if mc.Logger != nil {
+		return mc.Logger
+	}
+	return NewStdLogger()
+}
+
+// stdLogger : default Logger, backed by the standard library "log" package
+type stdLogger struct {
+	prefix string
+}
+
+// NewStdLogger : Logger writing through the standard library "log" package,
+// used by MispCon whenever no Logger is configured
+// return (Logger)
+func NewStdLogger() Logger {
+	return stdLogger{}
+}
+
+func (l stdLogger) logf(level, format string, args ...interface{}) {
+	stdlog.Printf("%s%s - %s", l.prefix, level, fmt.Sprintf(format, args...))
+}
+
+func (l stdLogger) Debugf(format string, args ...interface{}) { l.logf("DEBUG", format, args...) }
+func (l stdLogger) Infof(format string, args ...interface{})  { l.logf("INFO", format, args...) }
+func (l stdLogger) Warnf(format string, args ...interface{})  { l.logf("WARN", format, args...) }
+func (l stdLogger) Errorf(format string, args ...interface{}) { l.logf("ERROR", format, args...) }
+
+func (l stdLogger) With(keyvals ...interface{}) Logger {
+	return stdLogger{prefix: l.prefix + formatKeyvals(keyvals)}
+}
+
+func formatKeyvals(keyvals []interface{}) string {
+	var b strings.Builder
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		fmt.Fprintf(&b, "%v=%v ", keyvals[i], keyvals[i+1])
+	}
+	return b.String()
+}
+
+// noopLogger : Logger discarding every message
+type noopLogger struct{}
+
+// NewNoopLogger : Logger that discards every message, useful to silence a
+// MispCon entirely via MispConOptions.Logger
+// return (Logger)
+func NewNoopLogger() Logger {
+	return noopLogger{}
+}
+
+func (noopLogger) Debugf(string, ...interface{}) {}
+func (noopLogger) Infof(string, ...interface{})  {}
+func (noopLogger) Warnf(string, ...interface{})  {}
+func (noopLogger) Errorf(string, ...interface{}) {}
+func (l noopLogger) With(keyvals ...interface{}) Logger { return l }
@@ -2,6 +2,7 @@ package misp
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
@@ -17,8 +18,9 @@ import (
 
 	"github.com/0xrawsec/golang-utils/config"
 	"github.com/0xrawsec/golang-utils/datastructs"
-	"github.com/0xrawsec/golang-utils/log"
 	"github.com/0xrawsec/golang-utils/readers"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type MispError struct {
@@ -31,10 +33,14 @@ func (me MispError) Error() string {
 }
 
 type MispCon struct {
-	Proto  string
-	Host   string
-	APIKey string
-	Client *http.Client
+	Proto     string
+	Host      string
+	APIKey    string
+	Client    *http.Client
+	UserAgent string
+	// Logger receives every diagnostic message produced by this MispCon; nil
+	// falls back to NewStdLogger(), see MispConOptions.Logger to configure it
+	Logger Logger
 }
 
 type MispRequest struct {
@@ -50,12 +56,20 @@ type MispObject interface{}
 
 type MispResponse interface {
 	Iter() chan MispObject
+	// IterContext : behaves like Iter but stops feeding the channel and lets
+	// the producing goroutine return as soon as ctx is done
+	IterContext(ctx context.Context) chan MispObject
 }
 
 type EmptyMispResponse struct{}
 
 // Iter : MispResponse implementation
 func (emr EmptyMispResponse) Iter() chan MispObject {
+	return emr.IterContext(context.Background())
+}
+
+// IterContext : MispResponse implementation
+func (emr EmptyMispResponse) IterContext(ctx context.Context) chan MispObject {
 	c := make(chan MispObject)
 	close(c)
 	return c
@@ -80,6 +94,11 @@ type MispEventQuery struct {
 	WithAttachments string `json:"withAttachments,omitempty"`
 	Metadata        string `json:"metadata,omitempty"`
 	SearchAll       int8   `json:"searchall,omitempty"`
+	// Limit : number of results per page, passed through to restSearch's
+	// pagination so large result sets can be walked with bounded memory
+	Limit int `json:"limit,omitempty"`
+	// Page : page number fetched when Limit is set, 1-indexed
+	Page int `json:"page,omitempty"`
 }
 
 // Prepare : MispQuery Implementation
@@ -98,6 +117,13 @@ type Org struct {
 	UUID string `json:"uuid"`
 }
 
+// MispTag : tag attached to a MISP event or attribute
+type MispTag struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Colour string `json:"colour"`
+}
+
 // MispRelatedEvent definition
 type MispRelatedEvent struct {
 	ID            string `json:"id"`
@@ -115,13 +141,23 @@ type MispRelatedEvent struct {
 	Orgc          Org    `json:"Orgc"`
 }
 
-// Timestamp : return Time struct according to a string time
+// Timestamp : return Time struct according to a string time, panics if the
+// timestamp cannot be parsed; see TimestampE for an error-returning variant
 func (mre *MispRelatedEvent) Timestamp() time.Time {
-	sec, err := strconv.ParseInt(mre.StrTimestamp, 10, 64)
+	t, err := mre.TimestampE()
 	if err != nil {
 		panic(err)
 	}
-	return time.Unix(sec, 0)
+	return t
+}
+
+// TimestampE : return Time struct according to a string time
+func (mre *MispRelatedEvent) TimestampE() (time.Time, error) {
+	sec, err := strconv.ParseInt(mre.StrTimestamp, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0), nil
 }
 
 // MispEvent definition
@@ -148,24 +184,46 @@ type MispEvent struct {
 	ShadowAttribute       []MispAttribute    `json:"ShadowAttribute"`
 	RelatedEvent          []MispRelatedEvent `json:"RelatedEvent"`
 	Galaxy                []MispRelatedEvent `json:"Galaxy"`
+	Tag                   []MispTag          `json:"Tag"`
 }
 
-// Timestamp : return Time struct according to a string time
+// Timestamp : return Time struct according to a string time, panics if the
+// timestamp cannot be parsed; see TimestampE for an error-returning variant
 func (me MispEvent) Timestamp() time.Time {
-	sec, err := strconv.ParseInt(me.StrTimestamp, 10, 64)
+	t, err := me.TimestampE()
 	if err != nil {
 		panic(err)
 	}
-	return time.Unix(sec, 0)
+	return t
+}
+
+// TimestampE : return Time struct according to a string time
+func (me MispEvent) TimestampE() (time.Time, error) {
+	sec, err := strconv.ParseInt(me.StrTimestamp, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0), nil
 }
 
-// PublishedTimestamp : return Time struct according to a string time
+// PublishedTimestamp : return Time struct according to a string time, panics
+// if the timestamp cannot be parsed; see PublishedTimestampE for an
+// error-returning variant
 func (me MispEvent) PublishedTimestamp() time.Time {
-	sec, err := strconv.ParseInt(me.StrPublishedTimestamp, 10, 64)
+	t, err := me.PublishedTimestampE()
 	if err != nil {
 		panic(err)
 	}
-	return time.Unix(sec, 0)
+	return t
+}
+
+// PublishedTimestampE : return Time struct according to a string time
+func (me MispEvent) PublishedTimestampE() (time.Time, error) {
+	sec, err := strconv.ParseInt(me.StrPublishedTimestamp, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0), nil
 }
 
 // MispEventDict : intermediate structure to handle properly MISP API results
@@ -179,12 +237,22 @@ type MispEventResponse struct {
 }
 
 // Iter : MispResponse implementation
-func (mer MispEventResponse) Iter() (moc chan MispObject) {
+func (mer MispEventResponse) Iter() chan MispObject {
+	return mer.IterContext(context.Background())
+}
+
+// IterContext : MispResponse implementation, stops feeding the channel as
+// soon as ctx is done so the goroutine below does not leak
+func (mer MispEventResponse) IterContext(ctx context.Context) (moc chan MispObject) {
 	moc = make(chan MispObject)
 	go func() {
 		defer close(moc)
 		for _, me := range mer.Response {
-			moc <- me.Event
+			select {
+			case moc <- me.Event:
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
 	return
@@ -205,6 +273,11 @@ type MispAttributeQuery struct {
 	Last     string `json:"last,omitempty"`
 	EventID  string `json:"eventid,omitempty"`
 	UUID     string `json:"uuid,omitempty"`
+	// Limit : number of results per page, passed through to restSearch's
+	// pagination so large result sets can be walked with bounded memory
+	Limit int `json:"limit,omitempty"`
+	// Page : page number fetched when Limit is set, 1-indexed
+	Page int `json:"page,omitempty"`
 }
 
 // Prepare : MispQuery Implementation
@@ -227,12 +300,22 @@ type MispAttributeResponse struct {
 }
 
 // Iter : MispResponse implementation
-func (mar MispAttributeResponse) Iter() (moc chan MispObject) {
+func (mar MispAttributeResponse) Iter() chan MispObject {
+	return mar.IterContext(context.Background())
+}
+
+// IterContext : MispResponse implementation, stops feeding the channel as
+// soon as ctx is done so the goroutine below does not leak
+func (mar MispAttributeResponse) IterContext(ctx context.Context) (moc chan MispObject) {
 	moc = make(chan MispObject)
 	go func() {
 		defer close(moc)
 		for _, ma := range mar.Response.Attribute {
-			moc <- ma
+			select {
+			case moc <- ma:
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
 	return
@@ -254,13 +337,23 @@ type MispAttribute struct {
 	Comment        string `json:"comment"`
 }
 
-// Timestamp : return Time struct according to a string time
+// Timestamp : return Time struct according to a string time, panics if the
+// timestamp cannot be parsed; see TimestampE for an error-returning variant
 func (ma MispAttribute) Timestamp() time.Time {
-	sec, err := strconv.ParseInt(ma.StrTimestamp, 10, 64)
+	t, err := ma.TimestampE()
 	if err != nil {
 		panic(err)
 	}
-	return time.Unix(sec, 0)
+	return t
+}
+
+// TimestampE : return Time struct according to a string time
+func (ma MispAttribute) TimestampE() (time.Time, error) {
+	sec, err := strconv.ParseInt(ma.StrTimestamp, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0), nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -292,50 +385,85 @@ func headerSortedKeys(d http.Header) (sk []string) {
 	return
 }
 
-func logRequest(req *http.Request) {
+func (mc MispCon) logRequest(req *http.Request) error {
 	proxyURL, err := http.ProxyFromEnvironment(req)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
 	}
-	body, _ := req.GetBody()
-	log.Debugf("Proxy: %s", proxyURL)
-	log.Debugf("%s %s", req.Method, req.URL)
-	log.Debug("Header:")
+	bodyBytes, err := readAll(body)
+	if err != nil {
+		return err
+	}
+	logger := mc.log()
+	logger.Debugf("Proxy: %s", proxyURL)
+	logger.Debugf("%s %s", req.Method, req.URL)
+	logger.Debugf("Header:")
 	for _, sk := range headerSortedKeys(req.Header) {
 		for _, v := range req.Header[sk] {
-			log.Debugf("        %s: %v", sk, v)
+			logger.Debugf("        %s: %v", sk, v)
 		}
 	}
-	log.Debugf("Body: %s", string(readAllOrPanic(body)))
+	logger.Debugf("Body: %s", string(bodyBytes))
+	return nil
+}
+
+func readAll(r io.Reader) ([]byte, error) {
+	return ioutil.ReadAll(r)
 }
 
-func readAllOrPanic(r io.Reader) []byte {
-	respBody, err := ioutil.ReadAll(r)
+// LoadConfig : load a configuration file from path, panics if the
+// configuration cannot be loaded or is missing a required key; see
+// LoadConfigE for an error-returning variant
+// return (MispConfig)
+func LoadConfig(path string) MispConfig {
+	mc, err := LoadConfigE(path)
 	if err != nil {
 		panic(err)
 	}
-	return respBody
+	return mc
 }
 
-// LoadConfig : load a configuration file from path
-// return (MispConfig)
-func LoadConfig(path string) (mc MispConfig) {
+// LoadConfigE : load a configuration file from path. Unlike config.Config's
+// own GetRequiredString, a missing or malformed key here returns the
+// underlying error instead of calling os.Exit, so it is safe to use from a
+// long-running daemon.
+// return (MispConfig, error)
+func LoadConfigE(path string) (mc MispConfig, err error) {
 	conf, err := config.Load(path)
 	if err != nil {
-		panic(err)
+		return
 	}
-	mc.Proto = conf.GetRequiredString("protocol")
-	mc.Host = conf.GetRequiredString("host")
-	mc.APIKey = conf.GetRequiredString("api-key")
+	if mc.Proto, err = conf.GetString("protocol"); err != nil {
+		return
+	}
+	if mc.Host, err = conf.GetString("host"); err != nil {
+		return
+	}
+	mc.APIKey, err = conf.GetString("api-key")
 	return
 }
 
-// NewInsecureCon : Return a new MispCon with insecured TLS connection settings
+// NewInsecureCon : Return a new MispCon with insecured TLS connection
+// settings, panics on invalid protocol; see NewInsecureConE for an
+// error-returning variant
 // return (MispCon)
 func NewInsecureCon(proto, host, apiKey string) MispCon {
+	mc, err := NewInsecureConE(proto, host, apiKey)
+	if err != nil {
+		panic(err)
+	}
+	return mc
+}
+
+// NewInsecureConE : Return a new MispCon with insecured TLS connection settings
+// return (MispCon, error)
+func NewInsecureConE(proto, host, apiKey string) (MispCon, error) {
 	if proto != "http" && proto != "https" {
-		log.Errorf("%s : only http and https protocols are allowed", ErrUnknownProtocol.Error())
-		panic(ErrUnknownProtocol)
+		return MispCon{}, ErrUnknownProtocol
 	}
 	var noCertTransport http.RoundTripper = &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
@@ -350,17 +478,79 @@ func NewInsecureCon(proto, host, apiKey string) MispCon {
 		TLSClientConfig:       &tls.Config{InsecureSkipVerify: true},
 	}
 	c := http.Client{Transport: noCertTransport}
-	return MispCon{proto, host, apiKey, &c}
+	return MispCon{Proto: proto, Host: host, APIKey: apiKey, Client: &c}, nil
 }
 
-// NewCon : create a new MispCon struct
+// NewCon : create a new MispCon struct, panics on invalid protocol; see
+// NewConE for an error-returning variant
 // return (MispcCon)
 func NewCon(proto, host, apiKey string) MispCon {
+	mc, err := NewConE(proto, host, apiKey)
+	if err != nil {
+		panic(err)
+	}
+	return mc
+}
+
+// NewConE : create a new MispCon struct
+// return (MispCon, error)
+func NewConE(proto, host, apiKey string) (MispCon, error) {
 	if proto != "http" && proto != "https" {
-		log.Errorf("%s : only http and https protocols are allowed", ErrUnknownProtocol.Error())
-		panic(ErrUnknownProtocol)
+		return MispCon{}, ErrUnknownProtocol
 	}
-	return MispCon{proto, host, apiKey, &http.Client{}}
+	return MispCon{Proto: proto, Host: host, APIKey: apiKey, Client: &http.Client{}}, nil
+}
+
+// MispConOptions : configures the transport of a MispCon created via
+// NewConWithOptions
+type MispConOptions struct {
+	// Transport is the base http.RoundTripper every Middleware wraps,
+	// defaults to http.DefaultTransport
+	Transport http.RoundTripper
+	// Middlewares are applied innermost first: Middlewares[0] wraps Transport,
+	// Middlewares[1] wraps Middlewares[0], and so on
+	Middlewares []func(http.RoundTripper) http.RoundTripper
+	// MaxRetries : when > 0, NewConWithOptions appends a RetryMiddleware
+	// configured with MaxRetries/RetryBackoff on top of Middlewares
+	MaxRetries int
+	// RetryBackoff computes the delay before a given retry attempt, used by
+	// RetryMiddleware as a fallback when the response carries no Retry-After
+	RetryBackoff func(attempt int) time.Duration
+	// UserAgent overrides the default GolangMisp/<version> User-Agent header
+	UserAgent string
+	// Logger overrides the default standard library-backed Logger, see the
+	// Logger interface to plug in slog/zap/logrus
+	Logger Logger
+}
+
+// NewConWithOptions : create a new MispCon with a transport built by wrapping
+// opts.Transport with opts.Middlewares, plus a RetryMiddleware built from
+// opts.MaxRetries/opts.RetryBackoff when opts.MaxRetries > 0
+// return (MispCon, error)
+func NewConWithOptions(proto, host, apiKey string, opts MispConOptions) (MispCon, error) {
+	if proto != "http" && proto != "https" {
+		return MispCon{}, ErrUnknownProtocol
+	}
+
+	rt := opts.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for _, mw := range opts.Middlewares {
+		rt = mw(rt)
+	}
+	if opts.MaxRetries > 0 {
+		rt = RetryMiddleware(opts.MaxRetries, opts.RetryBackoff)(rt)
+	}
+
+	return MispCon{
+		Proto:     proto,
+		Host:      host,
+		APIKey:    apiKey,
+		Client:    &http.Client{Transport: rt},
+		UserAgent: opts.UserAgent,
+		Logger:    opts.Logger,
+	}, nil
 }
 
 func (mc MispCon) buildURL(path ...string) string {
@@ -370,80 +560,277 @@ func (mc MispCon) buildURL(path ...string) string {
 	return fmt.Sprintf("%s://%s/%s", mc.Proto, mc.Host, strings.Join(path, "/"))
 }
 
-func (mc MispCon) prepareRequest(method, url string, body io.Reader) (*http.Request, error) {
-	req, err := http.NewRequest(method, url, body)
+// SetTimeout : sets the timeout applied to every request issued by mc
+func (mc MispCon) SetTimeout(d time.Duration) {
+	mc.Client.Timeout = d
+}
+
+// version : library version reported in the default User-Agent header
+const version = "1.0.0"
+
+func (mc MispCon) userAgent() string {
+	if mc.UserAgent != "" {
+		return mc.UserAgent
+	}
+	return fmt.Sprintf("GolangMisp/%s (https://github.com/0xrawsec/golang-misp)", version)
+}
+
+func (mc MispCon) prepareRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
 	req.Header.Add("Authorization", mc.APIKey)
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Add("Accept", "application/json")
-	req.Header.Add("User-Agent", fmt.Sprintf("GolangMisp/%s (https://github.com/0xrawsec/golang-misp)", version))
+	req.Header.Add("User-Agent", mc.userAgent())
 	return req, err
 }
 
-func (mc MispCon) postSearch(kind string, mq *MispQuery) ([]byte, error) {
+// doSearch issues the restSearch/download request and returns the raw HTTP
+// response with its body left open, so callers can stream-decode it instead
+// of buffering the whole thing in memory. On a non-200 status the body is
+// read, closed and turned into a MispError.
+func (mc MispCon) doSearch(ctx context.Context, kind string, mq *MispQuery) (*http.Response, error) {
 	fullURL := mc.buildURL(kind, "restSearch", "download")
-	pReq, err := mc.prepareRequest("POST", fullURL, bytes.NewReader((*mq).Prepare()))
+	pReq, err := mc.prepareRequest(ctx, "POST", fullURL, bytes.NewReader((*mq).Prepare()))
 	if err != nil {
-		return []byte{}, err
+		return nil, err
 	}
-	if err != nil {
-		return []byte{}, err
+	if err := mc.logRequest(pReq); err != nil {
+		mc.log().Errorf("failed to log request: %s", err)
 	}
-	logRequest(pReq)
 	pResp, err := mc.Client.Do(pReq)
 	if err != nil {
-		return []byte{}, err
+		return nil, err
 	}
-	defer pResp.Body.Close()
-
-	respBody := readAllOrPanic(pResp.Body)
-	switch pResp.StatusCode {
-	case 200:
-		return respBody, err
-	default:
-		return []byte{}, MispError{pResp.StatusCode, string(respBody)}
+	if pResp.StatusCode != 200 {
+		defer pResp.Body.Close()
+		respBody, rErr := readAll(pResp.Body)
+		if rErr != nil {
+			return nil, rErr
+		}
+		return nil, MispError{pResp.StatusCode, string(respBody)}
 	}
+	return pResp, nil
 }
 
 // Search : Issue a search and return a MispObject
 // @mq : a struct implementing MispQuery interface
 // return (MispObject, error)
 func (mc MispCon) Search(mq MispQuery) (MispResponse, error) {
+	return mc.SearchContext(context.Background(), mq)
+}
+
+// SearchContext : behaves like Search but aborts the underlying HTTP request
+// as soon as ctx is done. The response body is decoded incrementally as
+// Iter/IterContext is consumed, so the memory used is bounded regardless of
+// the number of results; use MispEventQuery/MispAttributeQuery's Limit and
+// Page fields to walk very large result sets page by page.
+func (mc MispCon) SearchContext(ctx context.Context, mq MispQuery) (MispResponse, error) {
 	switch mq.(type) {
 	case MispAttributeQuery:
-		mar := MispAttributeResponse{}
-		bResp, err := mc.postSearch("attributes", &mq)
+		pResp, err := mc.doSearch(ctx, "attributes", &mq)
 		if err != nil {
-			log.Debugf("Error: %s", err)
+			mc.log().Debugf("Error: %s", err)
 			return EmptyMispResponse{}, err
 		}
-		err = json.Unmarshal(bResp, &mar)
+		sar, err := newStreamingAttributeResponse(pResp.Body, mc.log())
 		if err != nil {
-			log.Debug(string(bResp))
-			return mar, err
+			return EmptyMispResponse{}, err
 		}
-		return mar, nil
+		return sar, nil
 
 	case MispEventQuery:
-		mer := MispEventResponse{}
-		bResp, err := mc.postSearch("events", &mq)
+		pResp, err := mc.doSearch(ctx, "events", &mq)
 		if err != nil {
-			log.Debugf("Error: %s", err)
+			mc.log().Debugf("Error: %s", err)
 			return EmptyMispResponse{}, err
 		}
-		err = json.Unmarshal(bResp, &mer)
+		ser, err := newStreamingEventResponse(pResp.Body, mc.log())
 		if err != nil {
-			log.Debug(string(bResp))
-			return mer, err
+			return EmptyMispResponse{}, err
 		}
-		return mer, nil
+		return ser, nil
 	}
 	return EmptyMispResponse{}, fmt.Errorf("Empty Response")
 }
 
+////////////////////////////////////////////////////////////////////////////////
+////////////////////////////// Streaming decode /////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+// expectDelim consumes the next token from dec and fails unless it is want
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	d, ok := tok.(json.Delim)
+	if !ok || d != want {
+		return fmt.Errorf("expected %q, got %v", want, tok)
+	}
+	return nil
+}
+
+// skipToKey walks the object whose opening '{' has already been consumed
+// from dec, discarding unmatched key/value pairs until key is found, leaving
+// dec positioned to decode key's value next
+func skipToKey(dec *json.Decoder, key string) error {
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		k, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("expected an object key, got %v", tok)
+		}
+		if k == key {
+			return nil
+		}
+		var discard json.RawMessage
+		if err := dec.Decode(&discard); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("key %q not found in object", key)
+}
+
+// streamingAttributeResponse : MispResponse decoding response.Attribute one
+// element at a time instead of unmarshaling the whole search result, so
+// Iter/IterContext run in constant memory regardless of result set size
+type streamingAttributeResponse struct {
+	body   io.ReadCloser
+	dec    *json.Decoder
+	logger Logger
+}
+
+// newStreamingAttributeResponse walks body up to the first element of the
+// response.Attribute array, leaving it ready to be decoded element by element
+func newStreamingAttributeResponse(body io.ReadCloser, logger Logger) (*streamingAttributeResponse, error) {
+	dec := json.NewDecoder(body)
+	if err := expectDelim(dec, json.Delim('{')); err != nil {
+		body.Close()
+		return nil, err
+	}
+	if err := skipToKey(dec, "response"); err != nil {
+		body.Close()
+		return nil, err
+	}
+	if err := expectDelim(dec, json.Delim('{')); err != nil {
+		body.Close()
+		return nil, err
+	}
+	if err := skipToKey(dec, "Attribute"); err != nil {
+		body.Close()
+		return nil, err
+	}
+	if err := expectDelim(dec, json.Delim('[')); err != nil {
+		body.Close()
+		return nil, err
+	}
+	return &streamingAttributeResponse{body: body, dec: dec, logger: logger}, nil
+}
+
+// Iter : MispResponse implementation
+func (sar *streamingAttributeResponse) Iter() chan MispObject {
+	return sar.IterContext(context.Background())
+}
+
+// IterContext : MispResponse implementation, decodes one MispAttribute at a
+// time from the underlying response body and closes it once exhausted or ctx
+// is done
+func (sar *streamingAttributeResponse) IterContext(ctx context.Context) (moc chan MispObject) {
+	moc = make(chan MispObject)
+	go func() {
+		defer close(moc)
+		defer sar.body.Close()
+		for sar.dec.More() {
+			var ma MispAttribute
+			if err := sar.dec.Decode(&ma); err != nil {
+				sar.logger.Debugf("failed to decode attribute: %s", err)
+				return
+			}
+			select {
+			case moc <- ma:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return
+}
+
+// streamingEventResponse : MispResponse decoding the response array one
+// MispEventDict at a time instead of unmarshaling the whole search result, so
+// Iter/IterContext run in constant memory regardless of result set size
+type streamingEventResponse struct {
+	body   io.ReadCloser
+	dec    *json.Decoder
+	logger Logger
+}
+
+// newStreamingEventResponse walks body up to the first element of the
+// response array, leaving it ready to be decoded element by element
+func newStreamingEventResponse(body io.ReadCloser, logger Logger) (*streamingEventResponse, error) {
+	dec := json.NewDecoder(body)
+	if err := expectDelim(dec, json.Delim('{')); err != nil {
+		body.Close()
+		return nil, err
+	}
+	if err := skipToKey(dec, "response"); err != nil {
+		body.Close()
+		return nil, err
+	}
+	if err := expectDelim(dec, json.Delim('[')); err != nil {
+		body.Close()
+		return nil, err
+	}
+	return &streamingEventResponse{body: body, dec: dec, logger: logger}, nil
+}
+
+// Iter : MispResponse implementation
+func (ser *streamingEventResponse) Iter() chan MispObject {
+	return ser.IterContext(context.Background())
+}
+
+// IterContext : MispResponse implementation, decodes one MispEventDict at a
+// time from the underlying response body and closes it once exhausted or ctx
+// is done
+func (ser *streamingEventResponse) IterContext(ctx context.Context) (moc chan MispObject) {
+	moc = make(chan MispObject)
+	go func() {
+		defer close(moc)
+		defer ser.body.Close()
+		for ser.dec.More() {
+			var med MispEventDict
+			if err := ser.dec.Decode(&med); err != nil {
+				ser.logger.Debugf("failed to decode event: %s", err)
+				return
+			}
+			select {
+			case moc <- med.Event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return
+}
+
 // TextExport text export API wrapper https://<misp url>/attributes/text/download/
 // The wrapper takes care of removing the duplicated entries
 // @flags: the list of flags to use for the query
 func (mc MispCon) TextExport(flags ...string) (out []string, err error) {
+	return mc.TextExportContext(context.Background(), flags...)
+}
+
+// TextExportContext behaves like TextExport but stops streaming lines as
+// soon as ctx is done
+// @ctx : context controlling cancellation/deadline of the request
+// @flags: the list of flags to use for the query
+func (mc MispCon) TextExportContext(ctx context.Context, flags ...string) (out []string, err error) {
 	path := make([]string, 0)
 	path = append(path, "attributes", "text", "download")
 	path = append(path, flags...)
@@ -452,11 +839,13 @@ func (mc MispCon) TextExport(flags ...string) (out []string, err error) {
 
 	out = make([]string, 0)
 
-	pReq, err := mc.prepareRequest("GET", url, new(bytes.Buffer))
+	pReq, err := mc.prepareRequest(ctx, "GET", url, new(bytes.Buffer))
 	if err != nil {
 		return
 	}
-	logRequest(pReq)
+	if err := mc.logRequest(pReq); err != nil {
+		mc.log().Errorf("failed to log request: %s", err)
+	}
 	pResp, err := mc.Client.Do(pReq)
 	if err != nil {
 		return
@@ -466,15 +855,335 @@ func (mc MispCon) TextExport(flags ...string) (out []string, err error) {
 	case 200:
 		// used to remove duplicates
 		marked := datastructs.NewSyncedSet()
-		for line := range readers.Readlines(pResp.Body) {
-			txt := string(line)
-			if !marked.Contains(txt) {
-				out = append(out, txt)
+		lines := readers.Readlines(pResp.Body)
+	readLoop:
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					break readLoop
+				}
+				txt := string(line)
+				if !marked.Contains(txt) {
+					out = append(out, txt)
+				}
+				marked.Add(txt)
+			case <-ctx.Done():
+				err = ctx.Err()
+				break readLoop
 			}
-			marked.Add(txt)
 		}
 	default:
-		return out, MispError{pResp.StatusCode, string(readAllOrPanic(pResp.Body))}
+		respBody, rErr := readAll(pResp.Body)
+		if rErr != nil {
+			return out, rErr
+		}
+		return out, MispError{pResp.StatusCode, string(respBody)}
 	}
 	return
 }
+
+////////////////////////////////////////////////////////////////////////////////
+////////////////////////////////// Writes ///////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+// MispAttributeEnvelope : wraps a single MispAttribute the way the MISP API
+// expects it in attribute write request/response bodies
+type MispAttributeEnvelope struct {
+	Attribute MispAttribute `json:"Attribute"`
+}
+
+// MispSighting : define structure of a sighting object posted to the API
+type MispSighting struct {
+	ID          string `json:"id,omitempty"`
+	AttributeID string `json:"attribute_id,omitempty"`
+	EventID     string `json:"event_id,omitempty"`
+	Value       string `json:"value,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Timestamp   string `json:"timestamp,omitempty"`
+}
+
+// postJSON : marshals body, POSTs it to url and unmarshals the response into out
+// @out : pointer to decode the response into, may be nil if the caller does not
+// care about the response body
+func (mc MispCon) postJSON(ctx context.Context, url string, body, out interface{}) error {
+	jsBody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	pReq, err := mc.prepareRequest(ctx, "POST", url, bytes.NewReader(jsBody))
+	if err != nil {
+		return err
+	}
+	if err := mc.logRequest(pReq); err != nil {
+		mc.log().Errorf("failed to log request: %s", err)
+	}
+
+	pResp, err := mc.Client.Do(pReq)
+	if err != nil {
+		return err
+	}
+	defer pResp.Body.Close()
+
+	respBody, err := readAll(pResp.Body)
+	if err != nil {
+		return err
+	}
+	switch pResp.StatusCode {
+	case 200, 201:
+		if out == nil {
+			return nil
+		}
+		return json.Unmarshal(respBody, out)
+	default:
+		return MispError{pResp.StatusCode, string(respBody)}
+	}
+}
+
+// AddEvent : create a new event https://<misp url>/events/add
+func (mc MispCon) AddEvent(me MispEvent) (MispEvent, error) {
+	return mc.AddEventContext(context.Background(), me)
+}
+
+// AddEventContext behaves like AddEvent but aborts the underlying HTTP
+// request as soon as ctx is done
+func (mc MispCon) AddEventContext(ctx context.Context, me MispEvent) (out MispEvent, err error) {
+	dict := MispEventDict{}
+	err = mc.postJSON(ctx, mc.buildURL("events", "add"), MispEventDict{Event: me}, &dict)
+	return dict.Event, err
+}
+
+// UpdateEvent : update the event identified by me.ID https://<misp url>/events/edit/{id}
+func (mc MispCon) UpdateEvent(me MispEvent) (MispEvent, error) {
+	return mc.UpdateEventContext(context.Background(), me)
+}
+
+// UpdateEventContext behaves like UpdateEvent but aborts the underlying HTTP
+// request as soon as ctx is done
+func (mc MispCon) UpdateEventContext(ctx context.Context, me MispEvent) (out MispEvent, err error) {
+	dict := MispEventDict{}
+	err = mc.postJSON(ctx, mc.buildURL("events", "edit", me.ID), MispEventDict{Event: me}, &dict)
+	return dict.Event, err
+}
+
+// DeleteEvent : delete the event identified by id https://<misp url>/events/delete/{id}
+func (mc MispCon) DeleteEvent(id string) error {
+	return mc.DeleteEventContext(context.Background(), id)
+}
+
+// DeleteEventContext behaves like DeleteEvent but aborts the underlying HTTP
+// request as soon as ctx is done
+func (mc MispCon) DeleteEventContext(ctx context.Context, id string) error {
+	return mc.postJSON(ctx, mc.buildURL("events", "delete", id), struct{}{}, nil)
+}
+
+// PublishEvent : publish the event identified by id https://<misp url>/events/publish/{id}
+func (mc MispCon) PublishEvent(id string) error {
+	return mc.PublishEventContext(context.Background(), id)
+}
+
+// PublishEventContext behaves like PublishEvent but aborts the underlying
+// HTTP request as soon as ctx is done
+func (mc MispCon) PublishEventContext(ctx context.Context, id string) error {
+	return mc.postJSON(ctx, mc.buildURL("events", "publish", id), struct{}{}, nil)
+}
+
+// TagEvent : attach tag to the event identified by id https://<misp url>/events/addTag/{id}
+func (mc MispCon) TagEvent(id, tag string) error {
+	return mc.TagEventContext(context.Background(), id, tag)
+}
+
+// TagEventContext behaves like TagEvent but aborts the underlying HTTP
+// request as soon as ctx is done
+func (mc MispCon) TagEventContext(ctx context.Context, id, tag string) error {
+	body := struct {
+		Tag string `json:"tag"`
+	}{tag}
+	return mc.postJSON(ctx, mc.buildURL("events", "addTag", id), body, nil)
+}
+
+// AddAttribute : add an attribute to the event identified by eventID
+// https://<misp url>/attributes/add/{event_id}
+func (mc MispCon) AddAttribute(eventID string, ma MispAttribute) (MispAttribute, error) {
+	return mc.AddAttributeContext(context.Background(), eventID, ma)
+}
+
+// AddAttributeContext behaves like AddAttribute but aborts the underlying
+// HTTP request as soon as ctx is done
+func (mc MispCon) AddAttributeContext(ctx context.Context, eventID string, ma MispAttribute) (out MispAttribute, err error) {
+	env := MispAttributeEnvelope{}
+	err = mc.postJSON(ctx, mc.buildURL("attributes", "add", eventID), MispAttributeEnvelope{Attribute: ma}, &env)
+	return env.Attribute, err
+}
+
+// EditAttribute : edit the attribute identified by ma.ID https://<misp url>/attributes/edit/{id}
+func (mc MispCon) EditAttribute(ma MispAttribute) (MispAttribute, error) {
+	return mc.EditAttributeContext(context.Background(), ma)
+}
+
+// EditAttributeContext behaves like EditAttribute but aborts the underlying
+// HTTP request as soon as ctx is done
+func (mc MispCon) EditAttributeContext(ctx context.Context, ma MispAttribute) (out MispAttribute, err error) {
+	env := MispAttributeEnvelope{}
+	err = mc.postJSON(ctx, mc.buildURL("attributes", "edit", ma.ID), MispAttributeEnvelope{Attribute: ma}, &env)
+	return env.Attribute, err
+}
+
+// DeleteAttribute : delete the attribute identified by id https://<misp url>/attributes/delete/{id}
+func (mc MispCon) DeleteAttribute(id string) error {
+	return mc.DeleteAttributeContext(context.Background(), id)
+}
+
+// DeleteAttributeContext behaves like DeleteAttribute but aborts the
+// underlying HTTP request as soon as ctx is done
+func (mc MispCon) DeleteAttributeContext(ctx context.Context, id string) error {
+	return mc.postJSON(ctx, mc.buildURL("attributes", "delete", id), struct{}{}, nil)
+}
+
+// AddSighting : report a sighting https://<misp url>/sightings/add
+func (mc MispCon) AddSighting(ms MispSighting) (MispSighting, error) {
+	return mc.AddSightingContext(context.Background(), ms)
+}
+
+// AddSightingContext behaves like AddSighting but aborts the underlying
+// HTTP request as soon as ctx is done
+func (mc MispCon) AddSightingContext(ctx context.Context, ms MispSighting) (out MispSighting, err error) {
+	err = mc.postJSON(ctx, mc.buildURL("sightings", "add"), ms, &out)
+	return
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//////////////////////////////// Middlewares ////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+// roundTripperFunc : adapts a function to the http.RoundTripper interface
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter parses the Retry-After header of resp, either as a number of
+// seconds or as an HTTP date, returning 0 if absent or unparseable
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	ra := resp.Header.Get("Retry-After")
+	if ra == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(ra); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(ra); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// RetryMiddleware : builds a Middleware retrying idempotent requests up to
+// maxRetries times when the server answers 429 or 503 or the round trip
+// itself fails, honoring Retry-After and falling back to backoff(attempt)
+// when it is absent, unparseable or zero. Non-idempotent verbs (most
+// notably the POST-based restSearch/write endpoints) are never retried.
+func RetryMiddleware(maxRetries int, backoff func(attempt int) time.Duration) func(http.RoundTripper) http.RoundTripper {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !isIdempotent(req.Method) || maxRetries <= 0 {
+				return next.RoundTrip(req)
+			}
+
+			var resp *http.Response
+			var err error
+			for attempt := 0; attempt <= maxRetries; attempt++ {
+				if attempt > 0 {
+					delay := retryAfter(resp)
+					if delay <= 0 && backoff != nil {
+						delay = backoff(attempt)
+					}
+					// the previous attempt's response is being retried, close
+					// its body now so the transport can reuse/free the conn
+					if resp != nil {
+						resp.Body.Close()
+					}
+					if delay > 0 {
+						timer := time.NewTimer(delay)
+						select {
+						case <-timer.C:
+						case <-req.Context().Done():
+							timer.Stop()
+							return nil, req.Context().Err()
+						}
+					}
+				}
+
+				resp, err = next.RoundTrip(req)
+				if err != nil {
+					continue
+				}
+				if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+					return resp, nil
+				}
+			}
+			return resp, err
+		})
+	}
+}
+
+// LoggingMiddleware : builds a Middleware logging the method and URL of
+// every outbound request at debug level through logger, falling back to
+// NewStdLogger() when logger is nil
+func LoggingMiddleware(logger Logger) func(http.RoundTripper) http.RoundTripper {
+	if logger == nil {
+		logger = NewStdLogger()
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			logger.Debugf("%s %s", req.Method, req.URL)
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				logger.Debugf("%s %s failed: %s", req.Method, req.URL, err)
+				return resp, err
+			}
+			logger.Debugf("%s %s -> %d", req.Method, req.URL, resp.StatusCode)
+			return resp, nil
+		})
+	}
+}
+
+// TracingMiddleware : builds a Middleware starting an OpenTelemetry span
+// named "MISP <method>" around every outbound request, using the tracer
+// registered under tracerName
+func TracingMiddleware(tracerName string) func(http.RoundTripper) http.RoundTripper {
+	tracer := otel.Tracer(tracerName)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, span := tracer.Start(req.Context(), fmt.Sprintf("MISP %s", req.Method))
+			defer span.End()
+
+			span.SetAttributes(
+				attribute.String("http.method", req.Method),
+				attribute.String("http.url", req.URL.String()),
+			)
+
+			resp, err := next.RoundTrip(req.WithContext(ctx))
+			if err != nil {
+				span.RecordError(err)
+				return resp, err
+			}
+			span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+			return resp, nil
+		})
+	}
+}
@@ -6,15 +6,27 @@ import (
 	"github.com/0xrawsec/golang-utils/log"
 )
 
+// This file holds the integration tests that exercise a real MISP server,
+// configured via ./test/config.json; see unit_test.go for the self-contained
+// tests that don't need one
+
 var (
 	proto = ""
 	host  = ""
 	key   = ""
 )
 
+// init loads the live-server config used by the integration tests below.
+// A missing/invalid file leaves proto/host/key at their zero values instead
+// of panicking here; the integration tests below still need it, since
+// NewInsecureCon panics on the resulting empty protocol and that panic aborts
+// the whole test binary, unit_test.go's tests included
 func init() {
 	log.InitLogger(log.LDebug)
-	mc := LoadConfig("./test/config.json")
+	mc, err := LoadConfigE("./test/config.json")
+	if err != nil {
+		return
+	}
 	proto = mc.Proto
 	host = mc.Host
 	key = mc.APIKey
@@ -25,7 +37,7 @@ func TestSimpleAttributeSearch(t *testing.T) {
 	ma := MispAttributeQuery{Last: "1d"}
 	mr, err := con.Search(ma)
 	if err != nil {
-		t.Errorf("Failed to search: %s", err)
+		t.Errorf("Failed to search: %s", err)
 	}
 	for a := range mr.Iter() {
 		t.Log(a.(MispAttribute).Timestamp())
@@ -38,7 +50,7 @@ func TestSimpleEventSearch(t *testing.T) {
 	me := MispEventQuery{Last: "1d"}
 	mr, err := con.Search(me)
 	if err != nil {
-		t.Errorf("Failed to search: %s", err)
+		t.Errorf("Failed to search: %s", err)
 	}
 	for e := range mr.Iter() {
 		t.Log(e.(MispEvent).Timestamp())
@@ -46,6 +58,48 @@ func TestSimpleEventSearch(t *testing.T) {
 	}
 }
 
+func TestAddAndDeleteEvent(t *testing.T) {
+	con := NewInsecureCon(proto, host, key)
+	me, err := con.AddEvent(MispEvent{Info: "golang-misp test event", Distribution: "0"})
+	if err != nil {
+		t.Errorf("Failed to add event: %s", err)
+		t.FailNow()
+	}
+	t.Log(me)
+
+	if err := con.PublishEvent(me.ID); err != nil {
+		t.Errorf("Failed to publish event: %s", err)
+	}
+
+	if err := con.DeleteEvent(me.ID); err != nil {
+		t.Errorf("Failed to delete event: %s", err)
+	}
+}
+
+func TestAddAttributeAndSighting(t *testing.T) {
+	con := NewInsecureCon(proto, host, key)
+	me, err := con.AddEvent(MispEvent{Info: "golang-misp test event", Distribution: "0"})
+	if err != nil {
+		t.Errorf("Failed to add event: %s", err)
+		t.FailNow()
+	}
+
+	ma, err := con.AddAttribute(me.ID, MispAttribute{Category: "Network activity", Type: "ip-dst", Value: "8.8.8.8"})
+	if err != nil {
+		t.Errorf("Failed to add attribute: %s", err)
+		t.FailNow()
+	}
+	t.Log(ma)
+
+	if _, err := con.AddSighting(MispSighting{AttributeID: ma.ID}); err != nil {
+		t.Errorf("Failed to add sighting: %s", err)
+	}
+
+	if err := con.DeleteEvent(me.ID); err != nil {
+		t.Errorf("Failed to delete event: %s", err)
+	}
+}
+
 func TestTextExport(t *testing.T) {
 	con := NewInsecureCon(proto, host, key)
 	domains, err := con.TextExport("mutex")
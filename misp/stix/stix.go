@@ -0,0 +1,472 @@
+// Package stix bridges golang-misp's MispEvent/MispAttribute types with
+// STIX 2.1 domain objects and offers a minimal TAXII 2.1 client to exchange
+// the resulting bundles with the wider CTI ecosystem.
+package stix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0xrawsec/golang-misp/misp"
+)
+
+// STIX object types handled by this bridge
+const (
+	TypeIndicator = "indicator"
+	TypeReport    = "report"
+	TypeBundle    = "bundle"
+)
+
+// SpecVersion : STIX specification version produced and expected by this bridge
+const SpecVersion = "2.1"
+
+// KillChainPhase : STIX 2.1 kill-chain-phase type
+type KillChainPhase struct {
+	KillChainName string `json:"kill_chain_name"`
+	PhaseName     string `json:"phase_name"`
+}
+
+// Indicator : STIX 2.1 Indicator SDO
+type Indicator struct {
+	Type            string           `json:"type"`
+	SpecVersion     string           `json:"spec_version"`
+	ID              string           `json:"id"`
+	Created         string           `json:"created"`
+	Modified        string           `json:"modified"`
+	Name            string           `json:"name,omitempty"`
+	Pattern         string           `json:"pattern"`
+	PatternType     string           `json:"pattern_type"`
+	ValidFrom       string           `json:"valid_from"`
+	Labels          []string         `json:"labels,omitempty"`
+	KillChainPhases []KillChainPhase `json:"kill_chain_phases,omitempty"`
+}
+
+// Report : STIX 2.1 Report SDO bundling the objects extracted from a MispEvent
+type Report struct {
+	Type        string   `json:"type"`
+	SpecVersion string   `json:"spec_version"`
+	ID          string   `json:"id"`
+	Created     string   `json:"created"`
+	Modified    string   `json:"modified"`
+	Name        string   `json:"name"`
+	Published   string   `json:"published"`
+	Labels      []string `json:"labels,omitempty"`
+	ObjectRefs  []string `json:"object_refs"`
+}
+
+// Bundle : STIX 2.1 Bundle, the top-level object pushed to or pulled from a
+// TAXII collection. Objects is kept as raw JSON so round-tripping through a
+// TAXII server never loses fields this bridge does not model.
+type Bundle struct {
+	Type    string            `json:"type"`
+	ID      string            `json:"id"`
+	Objects []json.RawMessage `json:"objects"`
+}
+
+// attributeTypeToSTIXPath maps the common MISP attribute types this bridge
+// understands to the STIX cyber observable object path used in an
+// Indicator's pattern
+var attributeTypeToSTIXPath = map[string]string{
+	"ip-src":   "ipv4-addr:value",
+	"ip-dst":   "ipv4-addr:value",
+	"md5":      "file:hashes.MD5",
+	"sha1":     "file:hashes.'SHA-1'",
+	"sha256":   "file:hashes.'SHA-256'",
+	"domain":   "domain-name:value",
+	"url":      "url:value",
+	"filename": "file:name",
+}
+
+// stixPathToAttributeType is the reverse of attributeTypeToSTIXPath. Since
+// STIX has no separate src/dst notion for ipv4-addr, ipv4-addr:value always
+// maps back to ip-dst.
+var stixPathToAttributeType = map[string]string{
+	"ipv4-addr:value":       "ip-dst",
+	"file:hashes.MD5":       "md5",
+	"file:hashes.'SHA-1'":   "sha1",
+	"file:hashes.'SHA-256'": "sha256",
+	"domain-name:value":     "domain",
+	"url:value":             "url",
+	"file:name":             "filename",
+}
+
+var attributeTypeToCategory = map[string]string{
+	"ip-src":   "Network activity",
+	"ip-dst":   "Network activity",
+	"domain":   "Network activity",
+	"url":      "Network activity",
+	"md5":      "Payload delivery",
+	"sha1":     "Payload delivery",
+	"sha256":   "Payload delivery",
+	"filename": "Payload delivery",
+}
+
+func escapePatternValue(v string) string {
+	return strings.ReplaceAll(v, "'", "\\'")
+}
+
+func unescapePatternValue(v string) string {
+	return strings.ReplaceAll(v, "\\'", "'")
+}
+
+// parsePattern extracts the "<object path> = '<value>'" operands out of a
+// single-observation STIX pattern such as "[ipv4-addr:value = '1.2.3.4']"
+func parsePattern(pattern string) (path, value string, err error) {
+	trimmed := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(pattern), "["), "]")
+	parts := strings.SplitN(trimmed, "=", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("unsupported STIX pattern: %s", pattern)
+	}
+	path = strings.TrimSpace(parts[0])
+	value = unescapePatternValue(strings.Trim(strings.TrimSpace(parts[1]), "'"))
+	return path, value, nil
+}
+
+// tagsToLabels splits MISP tags into plain STIX labels and kill-chain-phase
+// tags, recognizing the "kill-chain:<phase>" convention for the latter
+func tagsToLabels(tags []misp.MispTag) (labels []string, phases []KillChainPhase) {
+	for _, tag := range tags {
+		if kc, phase, ok := strings.Cut(tag.Name, ":"); ok && strings.EqualFold(kc, "kill-chain") {
+			phases = append(phases, KillChainPhase{KillChainName: "mitre-attack", PhaseName: phase})
+			continue
+		}
+		labels = append(labels, strings.ToLower(tag.Name))
+	}
+	return
+}
+
+func uuidOrID(uuid, id string) string {
+	if uuid != "" {
+		return uuid
+	}
+	return id
+}
+
+// AttributeToIndicator converts a MispAttribute into a STIX 2.1 Indicator.
+// Only the attribute types listed in attributeTypeToSTIXPath are supported;
+// anything else returns an error so callers can decide to skip it.
+func AttributeToIndicator(ma misp.MispAttribute) (Indicator, error) {
+	path, ok := attributeTypeToSTIXPath[ma.Type]
+	if !ok {
+		return Indicator{}, fmt.Errorf("unsupported MISP attribute type for STIX conversion: %s", ma.Type)
+	}
+
+	ts, err := ma.TimestampE()
+	if err != nil {
+		return Indicator{}, err
+	}
+	created := ts.UTC().Format(time.RFC3339)
+
+	return Indicator{
+		Type:        TypeIndicator,
+		SpecVersion: SpecVersion,
+		ID:          "indicator--" + uuidOrID(ma.UUID, ma.ID),
+		Created:     created,
+		Modified:    created,
+		Name:        ma.Comment,
+		Pattern:     fmt.Sprintf("[%s = '%s']", path, escapePatternValue(ma.Value)),
+		PatternType: "stix",
+		ValidFrom:   created,
+	}, nil
+}
+
+// IndicatorToAttribute converts a STIX 2.1 Indicator back into a
+// MispAttribute. Only single-observation patterns over an object path listed
+// in stixPathToAttributeType are supported.
+func IndicatorToAttribute(ind Indicator) (misp.MispAttribute, error) {
+	path, value, err := parsePattern(ind.Pattern)
+	if err != nil {
+		return misp.MispAttribute{}, err
+	}
+
+	attrType, ok := stixPathToAttributeType[path]
+	if !ok {
+		return misp.MispAttribute{}, fmt.Errorf("unsupported STIX object path for MISP conversion: %s", path)
+	}
+
+	ts, err := time.Parse(time.RFC3339, ind.Created)
+	if err != nil {
+		return misp.MispAttribute{}, err
+	}
+
+	return misp.MispAttribute{
+		UUID:         strings.TrimPrefix(ind.ID, "indicator--"),
+		Type:         attrType,
+		Category:     attributeTypeToCategory[attrType],
+		Value:        value,
+		Comment:      ind.Name,
+		StrTimestamp: strconv.FormatInt(ts.Unix(), 10),
+	}, nil
+}
+
+// EventToBundle converts a MispEvent into a STIX 2.1 Bundle: every attribute
+// convertible by AttributeToIndicator becomes an Indicator, the event itself
+// becomes a Report referencing them, and the event's tags become Report
+// labels/kill-chain phases applied to each Indicator.
+func EventToBundle(me misp.MispEvent) (Bundle, error) {
+	labels, phases := tagsToLabels(me.Tag)
+
+	objects := make([]json.RawMessage, 0, len(me.Attribute)+1)
+	refs := make([]string, 0, len(me.Attribute))
+
+	for _, ma := range me.Attribute {
+		ind, err := AttributeToIndicator(ma)
+		if err != nil {
+			// not every MISP attribute type maps to STIX, skip those that don't
+			continue
+		}
+		ind.KillChainPhases = phases
+
+		raw, err := json.Marshal(ind)
+		if err != nil {
+			return Bundle{}, err
+		}
+		objects = append(objects, raw)
+		refs = append(refs, ind.ID)
+	}
+
+	created, err := me.TimestampE()
+	if err != nil {
+		return Bundle{}, err
+	}
+	createdStr := created.UTC().Format(time.RFC3339)
+
+	id := uuidOrID(me.UUID, me.ID)
+	report := Report{
+		Type:        TypeReport,
+		SpecVersion: SpecVersion,
+		ID:          "report--" + id,
+		Created:     createdStr,
+		Modified:    createdStr,
+		Name:        me.Info,
+		Published:   createdStr,
+		Labels:      labels,
+		ObjectRefs:  refs,
+	}
+
+	rawReport, err := json.Marshal(report)
+	if err != nil {
+		return Bundle{}, err
+	}
+	objects = append(objects, rawReport)
+
+	return Bundle{
+		Type:    TypeBundle,
+		ID:      "bundle--" + id,
+		Objects: objects,
+	}, nil
+}
+
+// BundleToEvent materializes the MispEvent described by a Bundle's Report
+// object, attaching every Indicator it references that IndicatorToAttribute
+// understands
+func BundleToEvent(b Bundle) (misp.MispEvent, error) {
+	var me misp.MispEvent
+	var report *Report
+	indicators := make(map[string]Indicator)
+
+	for _, raw := range b.Objects {
+		var env struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(raw, &env); err != nil {
+			return me, err
+		}
+
+		switch env.Type {
+		case TypeIndicator:
+			var ind Indicator
+			if err := json.Unmarshal(raw, &ind); err != nil {
+				return me, err
+			}
+			indicators[ind.ID] = ind
+		case TypeReport:
+			var rep Report
+			if err := json.Unmarshal(raw, &rep); err != nil {
+				return me, err
+			}
+			report = &rep
+		}
+	}
+
+	if report == nil {
+		return me, fmt.Errorf("bundle %s has no report object, cannot materialize a MispEvent", b.ID)
+	}
+
+	ts, err := time.Parse(time.RFC3339, report.Created)
+	if err != nil {
+		return me, err
+	}
+
+	me.UUID = strings.TrimPrefix(report.ID, "report--")
+	me.Info = report.Name
+	me.StrTimestamp = strconv.FormatInt(ts.Unix(), 10)
+
+	for _, ref := range report.ObjectRefs {
+		ind, ok := indicators[ref]
+		if !ok {
+			continue
+		}
+		ma, err := IndicatorToAttribute(ind)
+		if err != nil {
+			// not every Indicator pattern is one this bridge understands, skip it
+			continue
+		}
+		me.Attribute = append(me.Attribute, ma)
+	}
+
+	return me, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//////////////////////////////////// TAXII ///////////////////////////////////////
+////////////////////////////////////////////////////////////////////////////////
+
+// TaxiiClient : minimal TAXII 2.1 client able to push a Bundle's objects to a
+// collection or poll a collection back into MispEvents
+type TaxiiClient struct {
+	Client       *http.Client
+	APIRoot      string
+	CollectionID string
+	Username     string
+	Password     string
+}
+
+// NewTaxiiClient : create a TaxiiClient targeting the collection identified
+// by collectionID under apiRoot
+// return (*TaxiiClient)
+func NewTaxiiClient(apiRoot, collectionID, username, password string) *TaxiiClient {
+	return &TaxiiClient{
+		Client:       &http.Client{},
+		APIRoot:      strings.TrimRight(apiRoot, "/"),
+		CollectionID: collectionID,
+		Username:     username,
+		Password:     password,
+	}
+}
+
+func (tc *TaxiiClient) objectsURL() string {
+	return fmt.Sprintf("%s/collections/%s/objects/", tc.APIRoot, tc.CollectionID)
+}
+
+func (tc *TaxiiClient) do(req *http.Request) (*http.Response, error) {
+	if tc.Username != "" || tc.Password != "" {
+		req.SetBasicAuth(tc.Username, tc.Password)
+	}
+	req.Header.Set("Accept", "application/taxii+json;version=2.1")
+	return tc.Client.Do(req)
+}
+
+// Push : send a bundle's objects to the configured collection
+// https://<taxii root>/collections/{id}/objects/
+func (tc *TaxiiClient) Push(ctx context.Context, bundle Bundle) error {
+	body, err := json.Marshal(struct {
+		Objects []json.RawMessage `json:"objects"`
+	}{bundle.Objects})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tc.objectsURL(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/taxii+json;version=2.1")
+
+	resp, err := tc.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("TAXII push failed (HTTP %d): %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// Poll : fetch every object currently in the configured collection and
+// materialize one MispEvent per STIX Report found, attaching the Indicators
+// it references
+// https://<taxii root>/collections/{id}/objects/
+func (tc *TaxiiClient) Poll(ctx context.Context) ([]misp.MispEvent, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tc.objectsURL(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := tc.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("TAXII poll failed (HTTP %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var envelope struct {
+		Objects []json.RawMessage `json:"objects"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, err
+	}
+
+	indicatorsByID := make(map[string]json.RawMessage)
+	var rawReports []json.RawMessage
+
+	for _, raw := range envelope.Objects {
+		var env struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(raw, &env); err != nil {
+			return nil, err
+		}
+
+		switch env.Type {
+		case TypeIndicator:
+			var ind struct {
+				ID string `json:"id"`
+			}
+			if err := json.Unmarshal(raw, &ind); err != nil {
+				return nil, err
+			}
+			indicatorsByID[ind.ID] = raw
+		case TypeReport:
+			rawReports = append(rawReports, raw)
+		}
+	}
+
+	events := make([]misp.MispEvent, 0, len(rawReports))
+	for _, rawReport := range rawReports {
+		var rep Report
+		if err := json.Unmarshal(rawReport, &rep); err != nil {
+			return nil, err
+		}
+
+		objects := make([]json.RawMessage, 0, len(rep.ObjectRefs)+1)
+		for _, ref := range rep.ObjectRefs {
+			if raw, ok := indicatorsByID[ref]; ok {
+				objects = append(objects, raw)
+			}
+		}
+		objects = append(objects, rawReport)
+
+		me, err := BundleToEvent(Bundle{Type: TypeBundle, ID: "bundle--" + strings.TrimPrefix(rep.ID, "report--"), Objects: objects})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, me)
+	}
+
+	return events, nil
+}
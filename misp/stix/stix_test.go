@@ -0,0 +1,86 @@
+package stix
+
+import (
+	"testing"
+
+	"github.com/0xrawsec/golang-misp/misp"
+)
+
+func TestAttributeIndicatorRoundTrip(t *testing.T) {
+	ma := misp.MispAttribute{
+		UUID:         "11111111-1111-1111-1111-111111111111",
+		Type:         "ip-dst",
+		Category:     "Network activity",
+		Value:        "8.8.8.8",
+		StrTimestamp: "1700000000",
+	}
+
+	ind, err := AttributeToIndicator(ma)
+	if err != nil {
+		t.Fatalf("AttributeToIndicator failed: %s", err)
+	}
+	if ind.Pattern != "[ipv4-addr:value = '8.8.8.8']" {
+		t.Errorf("unexpected pattern: %s", ind.Pattern)
+	}
+
+	back, err := IndicatorToAttribute(ind)
+	if err != nil {
+		t.Fatalf("IndicatorToAttribute failed: %s", err)
+	}
+	if back.Value != ma.Value || back.Type != "ip-dst" {
+		t.Errorf("round trip mismatch: %+v", back)
+	}
+}
+
+func TestAttributeToIndicatorUnsupportedType(t *testing.T) {
+	ma := misp.MispAttribute{Type: "mutex", Value: "foo", StrTimestamp: "1700000000"}
+	if _, err := AttributeToIndicator(ma); err == nil {
+		t.Errorf("expected an error for an unsupported attribute type")
+	}
+}
+
+func TestEventBundleRoundTrip(t *testing.T) {
+	me := misp.MispEvent{
+		UUID:         "22222222-2222-2222-2222-222222222222",
+		Info:         "golang-misp stix test event",
+		StrTimestamp: "1700000000",
+		Tag:          []misp.MispTag{{Name: "kill-chain:exfiltration"}, {Name: "tlp:green"}},
+		Attribute: []misp.MispAttribute{
+			{UUID: "33333333-3333-3333-3333-333333333333", Type: "domain", Value: "evil.example", StrTimestamp: "1700000000"},
+			{UUID: "44444444-4444-4444-4444-444444444444", Type: "mutex", Value: "unsupported", StrTimestamp: "1700000000"},
+		},
+	}
+
+	bundle, err := EventToBundle(me)
+	if err != nil {
+		t.Fatalf("EventToBundle failed: %s", err)
+	}
+	// one indicator (domain) + the report, the mutex attribute is skipped
+	if len(bundle.Objects) != 2 {
+		t.Errorf("expected 2 objects, got %d", len(bundle.Objects))
+	}
+
+	back, err := BundleToEvent(bundle)
+	if err != nil {
+		t.Fatalf("BundleToEvent failed: %s", err)
+	}
+	if back.Info != me.Info {
+		t.Errorf("expected info %q, got %q", me.Info, back.Info)
+	}
+	if len(back.Attribute) != 1 || back.Attribute[0].Value != "evil.example" {
+		t.Errorf("unexpected attributes after round trip: %+v", back.Attribute)
+	}
+}
+
+func TestTagsToLabels(t *testing.T) {
+	labels, phases := tagsToLabels([]misp.MispTag{
+		{Name: "kill-chain:delivery"},
+		{Name: "TLP:RED"},
+	})
+	if len(phases) != 1 || phases[0].PhaseName != "delivery" {
+		t.Errorf("expected one kill-chain phase named delivery, got %+v", phases)
+	}
+	if len(labels) != 1 || labels[0] != "tlp:red" {
+		t.Errorf("expected one lowercase label, got %+v", labels)
+	}
+}
@@ -0,0 +1,144 @@
+package misp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// This file holds the self-contained, httptest-backed unit tests and
+// benchmark: unlike misp_test.go's integration tests, none of these talk to
+// a real MISP server, so they must not depend on its init()/config.json
+
+func TestNewConEBadProtocol(t *testing.T) {
+	if _, err := NewConE("ftp", "localhost", "key"); err != ErrUnknownProtocol {
+		t.Errorf("Expected ErrUnknownProtocol, got: %s", err)
+	}
+}
+
+func TestLoadConfigEMissingFile(t *testing.T) {
+	if _, err := LoadConfigE("./test/does-not-exist.json"); err == nil {
+		t.Errorf("Expected an error loading a missing config file")
+	}
+}
+
+func TestMispConDefaultsToStdLogger(t *testing.T) {
+	con, err := NewConE("http", "localhost", "key")
+	if err != nil {
+		t.Fatalf("Failed to build con: %s", err)
+	}
+	if _, ok := con.log().(stdLogger); !ok {
+		t.Errorf("Expected a MispCon without an explicit Logger to fall back to stdLogger, got %T", con.log())
+	}
+}
+
+func TestNoopLoggerDiscardsEverything(t *testing.T) {
+	con, err := NewConWithOptions("http", "localhost", "key", MispConOptions{Logger: NewNoopLogger()})
+	if err != nil {
+		t.Fatalf("Failed to build con: %s", err)
+	}
+	logger := con.log().With("request_id", "abc")
+	// exercised purely to make sure noopLogger never panics regardless of
+	// how many/which arguments are passed
+	logger.Debugf("debug %d", 1)
+	logger.Infof("info")
+	logger.Warnf("warn %s", "x")
+	logger.Errorf("error %v", fmt.Errorf("boom"))
+}
+
+func TestRetryMiddlewareRetriesServiceUnavailable(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	con, err := NewConWithOptions("http", srv.Listener.Addr().String(), "", MispConOptions{
+		MaxRetries:   3,
+		RetryBackoff: func(attempt int) time.Duration { return 0 },
+	})
+	if err != nil {
+		t.Fatalf("Failed to build con: %s", err)
+	}
+
+	req, err := con.prepareRequest(context.Background(), http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("Failed to build request: %s", err)
+	}
+
+	resp, err := con.Client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected final status 200, got %d after %d calls", resp.StatusCode, calls)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestSearchContextCancellation(t *testing.T) {
+	con := NewInsecureCon("http", "localhost", "key")
+	con.SetTimeout(30 * time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ma := MispAttributeQuery{Last: "1d"}
+	if _, err := con.SearchContext(ctx, ma); err == nil {
+		t.Errorf("Expected search to fail on a cancelled context")
+	}
+}
+
+// BenchmarkSearchAttributesStreaming demonstrates that walking a 100k
+// attribute restSearch response through SearchContext/Iter runs in constant
+// memory: the server streams the array out without ever holding it all in
+// memory, and so should the client
+func BenchmarkSearchAttributesStreaming(b *testing.B) {
+	const n = 100000
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"response":{"Attribute":[`)
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"id":"%d","type":"ip-dst","value":"8.8.8.8"}`, i)
+		}
+		fmt.Fprint(w, `]}}`)
+	}))
+	defer srv.Close()
+
+	con, err := NewConE("http", srv.Listener.Addr().String(), "")
+	if err != nil {
+		b.Fatalf("Failed to build con: %s", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mr, err := con.Search(MispAttributeQuery{Last: "30d"})
+		if err != nil {
+			b.Fatalf("Search failed: %s", err)
+		}
+		count := 0
+		for range mr.Iter() {
+			count++
+		}
+		if count != n {
+			b.Fatalf("expected %d attributes, got %d", n, count)
+		}
+	}
+}